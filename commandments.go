@@ -0,0 +1,279 @@
+// Package commandments builds CLI commands from typed configuration structs,
+// deriving flags from `flag:"name,usage"` struct tags via reflection.
+package commandments
+
+import (
+	stdflag "flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// flag describes a single command-line flag derived from a struct field.
+type flag struct {
+	name    string
+	usage   string
+	kind    reflect.Kind
+	choices []string
+}
+
+// CMD is a runnable command built from a typed configuration struct.
+type CMD struct {
+	name string
+	fs   *stdflag.FlagSet
+	run  func() error
+
+	cfgVal       reflect.Value
+	configFile   string
+	envPrefix    string
+	strictConfig bool
+	validators   []func(any) error
+	shortDesc    string
+	longDesc     string
+}
+
+// Name returns the command's name, as given to NewCMD/MustCMD.
+func (c *CMD) Name() string {
+	return c.name
+}
+
+// flags returns the flag metadata for this command's configuration struct.
+func (c *CMD) flags() []flag {
+	return flagsFromType(c.cfgVal.Type())
+}
+
+// Run resolves configuration in order of increasing precedence (defaults,
+// then config file, then environment variables, then CLI flags) and
+// invokes the WithConfig callback with the result. A hidden
+// "--generate-<shell>-completion" flag short-circuits Run to print a
+// completion script instead.
+func (c *CMD) Run(args []string) error {
+	if isHelpFlag(args) {
+		c.writeHelp(os.Stdout)
+		return nil
+	}
+	if shell, ok := generateCompletionShell(args); ok {
+		script, err := c.Completion(shell)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(os.Stdout, script)
+		return nil
+	}
+
+	configPath := c.configFile
+	if v, ok := scanConfigFlag(args); ok {
+		configPath = v
+	}
+	if configPath != "" {
+		resetAccumulators(c.fs)
+		if err := loadConfigFile(configPath, c.fs, c.strictConfig); err != nil {
+			return fmt.Errorf("commandments: %s: %w", c.name, err)
+		}
+	}
+
+	resetAccumulators(c.fs)
+	if err := applyEnv(c.fs, c.cfgVal.Type(), c.envPrefix); err != nil {
+		return fmt.Errorf("commandments: %s: %w", c.name, err)
+	}
+
+	resetAccumulators(c.fs)
+	if err := c.fs.Parse(args); err != nil {
+		return err
+	}
+
+	var errs ValidationErrors
+	if err := validateStructTags(c.cfgVal.Type(), c.cfgVal); err != nil {
+		errs = append(errs, err.(ValidationErrors)...)
+	}
+	for _, validate := range c.validators {
+		if err := validate(c.cfgVal.Interface()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("commandments: %s: %w", c.name, errs)
+	}
+
+	return c.run()
+}
+
+// Option configures a CMD during construction via NewCMD/MustCMD.
+type Option func(*builder) error
+
+// builder accumulates the state contributed by Options before a CMD is
+// assembled.
+type builder struct {
+	configType    reflect.Type
+	configFunc    any
+	defaultConfig any
+
+	configFile   string
+	envPrefix    string
+	strictConfig bool
+
+	customParsers map[string]func(string) (any, error)
+	validators    []func(any) error
+	shortDesc     string
+	longDesc      string
+}
+
+// WithConfig registers the callback invoked with the parsed configuration
+// once flags have been parsed. It also determines the configuration struct
+// type used to derive flags.
+func WithConfig[T any](fn func(config T) error) Option {
+	return func(b *builder) error {
+		b.configFunc = fn
+		b.configType = reflect.TypeOf(*new(T))
+		return nil
+	}
+}
+
+// WithDefaultConfig seeds the configuration struct with default values
+// before flags are bound, so unset flags fall back to these values.
+func WithDefaultConfig[T any](def T) Option {
+	return func(b *builder) error {
+		b.defaultConfig = def
+		return nil
+	}
+}
+
+// NewCMD builds a CMD from the given options. It returns an error if no
+// WithConfig option was supplied or if the configuration struct has an
+// unsupported field type.
+func NewCMD(name string, opts ...Option) (*CMD, error) {
+	b := &builder{}
+	for _, opt := range opts {
+		if err := opt(b); err != nil {
+			return nil, fmt.Errorf("commandments: %s: %w", name, err)
+		}
+	}
+	if b.configFunc == nil {
+		return nil, fmt.Errorf("commandments: %s: WithConfig is required", name)
+	}
+
+	cfgVal := reflect.New(b.configType).Elem()
+	if b.defaultConfig != nil {
+		cfgVal.Set(reflect.ValueOf(b.defaultConfig))
+	}
+
+	fs := stdflag.NewFlagSet(name, stdflag.ContinueOnError)
+	if err := bindFlags(fs, cfgVal, b.customParsers); err != nil {
+		return nil, fmt.Errorf("commandments: %s: %w", name, err)
+	}
+	if fs.Lookup("config") != nil {
+		return nil, fmt.Errorf("commandments: %s: field tagged flag:\"config\" conflicts with the built-in -config flag", name)
+	}
+	fs.String("config", b.configFile, "path to a JSON config file")
+
+	configFunc := b.configFunc
+	return &CMD{
+		name: name,
+		fs:   fs,
+		run: func() error {
+			return callConfigFunc(configFunc, cfgVal.Interface())
+		},
+		cfgVal:       cfgVal,
+		configFile:   b.configFile,
+		envPrefix:    b.envPrefix,
+		strictConfig: b.strictConfig,
+		validators:   b.validators,
+		shortDesc:    b.shortDesc,
+		longDesc:     b.longDesc,
+	}, nil
+}
+
+// MustCMD is like NewCMD but panics if construction fails. It is intended
+// for package-level command declarations where a construction error is a
+// programmer mistake.
+func MustCMD(name string, opts ...Option) *CMD {
+	cmd, err := NewCMD(name, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+// callConfigFunc invokes a func(T) error captured as any with cfg, which
+// must be an interface value of type T.
+func callConfigFunc(fn any, cfg any) error {
+	out := reflect.ValueOf(fn).Call([]reflect.Value{reflect.ValueOf(cfg)})
+	if err, _ := out[0].Interface().(error); err != nil {
+		return err
+	}
+	return nil
+}
+
+// splitFlagTag splits a `flag:"name,usage"` tag into its name and usage
+// parts.
+func splitFlagTag(tag string) (name, usage string) {
+	parts := strings.SplitN(tag, ",", 2)
+	name = strings.TrimSpace(parts[0])
+	if len(parts) > 1 {
+		usage = strings.TrimSpace(parts[1])
+	}
+	return name, usage
+}
+
+// bindFlags registers a stdlib flag for each tagged field of cfg on fs,
+// backed by that field's address so parsing writes directly into cfg. See
+// bindFlagValue for the supported field types.
+func bindFlags(fs *stdflag.FlagSet, cfg reflect.Value, customParsers map[string]func(string) (any, error)) error {
+	t := cfg.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("flag")
+		if tag == "" {
+			continue
+		}
+		name, usage := splitFlagTag(tag)
+		if err := bindFlagValue(fs, name, usage, cfg.Field(i), customParsers); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+// structToFlags derives the flag metadata for T's tagged fields, without
+// binding them to a FlagSet. It is used to introspect a configuration
+// struct's flags, e.g. for help and completion output.
+func structToFlags[T any]() ([]flag, error) {
+	return flagsFromType(reflect.TypeOf(*new(T))), nil
+}
+
+// flagsFromType derives the flag metadata for t's tagged fields.
+func flagsFromType(t reflect.Type) []flag {
+	flags := make([]flag, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("flag")
+		if tag == "" {
+			continue
+		}
+		name, usage := splitFlagTag(tag)
+		flags = append(flags, flag{
+			name:    name,
+			usage:   usage,
+			kind:    field.Type.Kind(),
+			choices: parseChoices(field.Tag.Get("choices")),
+		})
+	}
+	return flags
+}
+
+// parseChoices splits a `choices:"a,b,c"` struct tag into its candidate
+// values.
+func parseChoices(tag string) []string {
+	if tag == "" {
+		return nil
+	}
+	parts := strings.Split(tag, ",")
+	choices := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			choices = append(choices, p)
+		}
+	}
+	return choices
+}
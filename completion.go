@@ -0,0 +1,131 @@
+package commandments
+
+import (
+	"fmt"
+	"strings"
+)
+
+// completionShells lists the shells renderCompletion knows how to target.
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// generateCompletionShell reports whether args contains a hidden
+// "--generate-<shell>-completion" flag, and if so which shell it names.
+func generateCompletionShell(args []string) (string, bool) {
+	for _, a := range args {
+		for _, shell := range completionShells {
+			if a == "--generate-"+shell+"-completion" {
+				return shell, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Completion renders a shell completion script for c. shell is one of
+// "bash", "zsh", "fish", or "powershell".
+func (c *CMD) Completion(shell string) (string, error) {
+	return renderCompletion(c.name, c.flags(), nil, shell)
+}
+
+// Completion renders a shell completion script covering every subcommand
+// at every depth reachable from g, plus every persistent flag declared
+// anywhere in the tree. shell is one of "bash", "zsh", "fish", or
+// "powershell".
+func (g *Group) Completion(shell string) (string, error) {
+	flags, subcommands := g.collectTree()
+	return renderCompletion(g.name, flags, subcommands, shell)
+}
+
+func renderCompletion(name string, flags []flag, subcommands []string, shell string) (string, error) {
+	switch shell {
+	case "bash":
+		return bashCompletion(name, flags, subcommands), nil
+	case "zsh":
+		return zshCompletion(name, flags, subcommands), nil
+	case "fish":
+		return fishCompletion(name, flags, subcommands), nil
+	case "powershell":
+		return powershellCompletion(name, flags, subcommands), nil
+	default:
+		return "", fmt.Errorf("commandments: unsupported completion shell %q (want one of %s)",
+			shell, strings.Join(completionShells, ", "))
+	}
+}
+
+// completionWords lists every word a completer should offer: each flag
+// (and its choices, if declared) plus every subcommand name.
+func completionWords(flags []flag, subcommands []string) []string {
+	words := make([]string, 0, len(flags)+len(subcommands))
+	for _, f := range flags {
+		words = append(words, "--"+f.name)
+		words = append(words, f.choices...)
+	}
+	words = append(words, subcommands...)
+	return words
+}
+
+func sanitize(name string) string {
+	return strings.NewReplacer("-", "_", " ", "_").Replace(name)
+}
+
+func bashCompletion(name string, flags []flag, subcommands []string) string {
+	fn := "_" + sanitize(name) + "_completions"
+	var b strings.Builder
+	fmt.Fprintf(&b, "# bash completion for %s\n", name)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	b.WriteString("  local cur words\n")
+	b.WriteString("  cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	fmt.Fprintf(&b, "  words=\"%s\"\n", strings.Join(completionWords(flags, subcommands), " "))
+	b.WriteString("  COMPREPLY=( $(compgen -W \"${words}\" -- \"${cur}\") )\n")
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "complete -F %s %s\n", fn, name)
+	return b.String()
+}
+
+func zshCompletion(name string, flags []flag, subcommands []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#compdef %s\n\n", name)
+	fmt.Fprintf(&b, "_%s() {\n", sanitize(name))
+	b.WriteString("  local -a words\n")
+	fmt.Fprintf(&b, "  words=(%s)\n", strings.Join(completionWords(flags, subcommands), " "))
+	b.WriteString("  _describe 'command' words\n")
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, "compdef _%s %s\n", sanitize(name), name)
+	return b.String()
+}
+
+func fishCompletion(name string, flags []flag, subcommands []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# fish completion for %s\n", name)
+	for _, f := range flags {
+		fmt.Fprintf(&b, "complete -c %s -l %s -d %q\n", name, f.name, f.usage)
+		for _, choice := range f.choices {
+			fmt.Fprintf(&b, "complete -c %s -l %s -a %q\n", name, f.name, choice)
+		}
+	}
+	for _, sub := range subcommands {
+		fmt.Fprintf(&b, "complete -c %s -n __fish_use_subcommand -a %q\n", name, sub)
+	}
+	return b.String()
+}
+
+func powershellCompletion(name string, flags []flag, subcommands []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# PowerShell completion for %s\n", name)
+	fmt.Fprintf(&b, "Register-ArgumentCompleter -Native -CommandName %s -ScriptBlock {\n", name)
+	b.WriteString("  param($wordToComplete, $commandAst, $cursorPosition)\n")
+	fmt.Fprintf(&b, "  $words = @(%s)\n", quotedPowershellList(completionWords(flags, subcommands)))
+	b.WriteString("  $words | Where-Object { $_ -like \"$wordToComplete*\" } | ForEach-Object {\n")
+	b.WriteString("    [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)\n")
+	b.WriteString("  }\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func quotedPowershellList(words []string) string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = "'" + w + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
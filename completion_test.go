@@ -0,0 +1,76 @@
+package commandments
+
+import (
+	"strings"
+	"testing"
+)
+
+type completionTestArgs struct {
+	LogLevel string `flag:"log-level,log verbosity" choices:"debug,info,warn,error"`
+}
+
+func TestStructToFlagsRecordsChoices(t *testing.T) {
+	res, err := structToFlags[completionTestArgs]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 {
+		t.Fatalf("expected 1 flag, got %d", len(res))
+	}
+	want := []string{"debug", "info", "warn", "error"}
+	got := res[0].choices
+	if len(got) != len(want) {
+		t.Fatalf("expected choices %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected choices %v, got %v", want, got)
+		}
+	}
+}
+
+func TestCMDCompletionShells(t *testing.T) {
+	cmd, err := NewCMD("app", WithConfig(func(config completionTestArgs) error { return nil }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		script, err := cmd.Completion(shell)
+		if err != nil {
+			t.Errorf("%s: %v", shell, err)
+		}
+		if script == "" {
+			t.Errorf("%s: expected non-empty completion script", shell)
+		}
+	}
+	if _, err := cmd.Completion("nushell"); err == nil {
+		t.Error("expected an error for an unsupported shell")
+	}
+}
+
+func TestGroupCompletionCoversNestedDepthAndPersistentFlags(t *testing.T) {
+	up, err := NewCMD("up", WithConfig(func(config completionTestArgs) error { return nil }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := NewGroup("myapp")
+	root.PersistentBool("verbose", false, "enable verbose logging")
+
+	db := NewGroup("db")
+	migrate := NewGroup("migrate")
+	migrate.PersistentString("dsn", "", "database connection string")
+	migrate.AddCommand("up", up)
+	db.AddGroup(migrate)
+	root.AddGroup(db)
+
+	script, err := root.Completion("bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"db", "migrate", "up", "--verbose", "--dsn", "--log-level"} {
+		if !strings.Contains(script, want) {
+			t.Errorf("expected completion script to mention %q, got:\n%s", want, script)
+		}
+	}
+}
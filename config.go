@@ -0,0 +1,208 @@
+package commandments
+
+import (
+	"encoding/json"
+	stdflag "flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WithEnvPrefix enables environment-variable fallback for flags: a tagged
+// field's value is read from PREFIX_NAME (the flag name upper-cased with
+// "-" replaced by "_"), unless an `env:"..."` struct tag gives an explicit
+// variable name. A field with an `env:"..."` tag is read from that variable
+// even without WithEnvPrefix. Precedence is CLI flag > env var > config
+// file > default.
+func WithEnvPrefix(prefix string) Option {
+	return func(b *builder) error {
+		b.envPrefix = prefix
+		return nil
+	}
+}
+
+// WithConfigFile sets the path to a JSON config file used to fill in flag
+// defaults. A `-config` flag is also registered on the command so callers
+// can override the path at runtime.
+func WithConfigFile(path string) Option {
+	return func(b *builder) error {
+		b.configFile = path
+		return nil
+	}
+}
+
+// WithStrictConfig rejects config files that contain keys not matching any
+// registered flag. Without it, unknown keys are silently ignored.
+func WithStrictConfig() Option {
+	return func(b *builder) error {
+		b.strictConfig = true
+		return nil
+	}
+}
+
+// scanConfigFlag looks for a "-config"/"--config" flag in args without
+// going through the FlagSet, since the config file must be loaded before
+// the rest of the flags are parsed.
+func scanConfigFlag(args []string) (string, bool) {
+	for i, a := range args {
+		switch {
+		case a == "-config" || a == "--config":
+			if i+1 < len(args) {
+				return args[i+1], true
+			}
+		case strings.HasPrefix(a, "-config="):
+			return strings.TrimPrefix(a, "-config="), true
+		case strings.HasPrefix(a, "--config="):
+			return strings.TrimPrefix(a, "--config="), true
+		}
+	}
+	return "", false
+}
+
+// loadConfigFile reads path and applies its values onto fs's flags via
+// fs.Set, so each flag's own bindFlagValue-selected parsing logic (the
+// same logic CLI flags use) runs on the config file value. A missing file
+// is not an error, so WithConfigFile can point at an optional,
+// commonly-absent path.
+func loadConfigFile(path string, fs *stdflag.FlagSet, strict bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json", "":
+		return decodeJSONConfig(data, fs, strict)
+	default:
+		return fmt.Errorf("config file %s: unsupported format %q (only .json is currently supported)", path, ext)
+	}
+}
+
+// decodeJSONConfig applies the JSON object in data onto fs's flags, keyed
+// by flag name, converting each JSON value to the string form its flag's
+// Value.Set expects.
+func decodeJSONConfig(data []byte, fs *stdflag.FlagSet, strict bool) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	for name, val := range raw {
+		if fs.Lookup(name) == nil {
+			if strict {
+				return fmt.Errorf("config file: unknown key %q", name)
+			}
+			continue
+		}
+
+		var v any
+		if err := json.Unmarshal(val, &v); err != nil {
+			return fmt.Errorf("config key %q: %w", name, err)
+		}
+		s, err := jsonValueToFlagString(v)
+		if err != nil {
+			return fmt.Errorf("config key %q: %w", name, err)
+		}
+		if err := fs.Set(name, s); err != nil {
+			return fmt.Errorf("config key %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// jsonValueToFlagString renders a decoded JSON value as the string a
+// flag.Value's Set expects: scalars as-is, arrays comma-joined, and
+// objects as comma-separated key=value pairs (matching stringSliceValue,
+// intSliceValue, and stringMapValue).
+func jsonValueToFlagString(v any) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return val, nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	case float64:
+		if val == float64(int64(val)) {
+			return strconv.FormatInt(int64(val), 10), nil
+		}
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	case []any:
+		parts := make([]string, len(val))
+		for i, item := range val {
+			s, err := jsonValueToFlagString(item)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = s
+		}
+		return strings.Join(parts, ","), nil
+	case map[string]any:
+		parts := make([]string, 0, len(val))
+		for k, item := range val {
+			s, err := jsonValueToFlagString(item)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, k+"="+s)
+		}
+		return strings.Join(parts, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported JSON value type %T", v)
+	}
+}
+
+// applyEnv sets fs's flags from matching environment variables, via
+// fs.Set, so each flag's own bindFlagValue-selected parsing logic (the
+// same logic CLI flags use) runs on the environment value.
+func applyEnv(fs *stdflag.FlagSet, cfgType reflect.Type, prefix string) error {
+	for i := 0; i < cfgType.NumField(); i++ {
+		field := cfgType.Field(i)
+		tag := field.Tag.Get("flag")
+		if tag == "" {
+			continue
+		}
+		name, _ := splitFlagTag(tag)
+
+		envName := field.Tag.Get("env")
+		if envName == "" {
+			if prefix == "" {
+				continue
+			}
+			envName = prefix + "_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		}
+
+		v, ok := os.LookupEnv(envName)
+		if !ok {
+			continue
+		}
+
+		if err := fs.Set(name, v); err != nil {
+			return fmt.Errorf("env %s: %w", envName, err)
+		}
+	}
+	return nil
+}
+
+// resettable lets a flag.Value that accumulates across repeated Set calls
+// (e.g. stringSliceValue for repeated --tag=x --tag=y) be told "the next
+// Set call starts a new layer", so config-file, env, and CLI values each
+// replace the prior layer instead of appending to it.
+type resettable interface {
+	reset()
+}
+
+// resetAccumulators resets every resettable flag.Value on fs, so the next
+// precedence layer (config file, then env, then CLI) starts fresh.
+func resetAccumulators(fs *stdflag.FlagSet) {
+	fs.VisitAll(func(f *stdflag.Flag) {
+		if r, ok := f.Value.(resettable); ok {
+			r.reset()
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package commandments
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type configTestArgs struct {
+	Host string `flag:"host,do host stuff"`
+	Port int    `flag:"port,set port" env:"APP_PORT"`
+}
+
+type conflictingConfigArgs struct {
+	Config string `flag:"config,the app's own config blob"`
+}
+
+func TestNewCMDErrorsOnConfigFlagCollision(t *testing.T) {
+	_, err := NewCMD("config-test", WithConfig(func(config conflictingConfigArgs) error {
+		return nil
+	}))
+	if err == nil {
+		t.Fatal("expected an error when a field is tagged flag:\"config\"")
+	}
+}
+
+func TestConfigFilePrecedence(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"host":"fromfile","port":9000}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var got configTestArgs
+	cmd, err := NewCMD("config-test", WithConfigFile(path), WithConfig(func(config configTestArgs) error {
+		got = config
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Run(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got.Host != "fromfile" || got.Port != 9000 {
+		t.Errorf("expected values from config file, got %+v", got)
+	}
+}
+
+func TestEnvOverridesConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"port":9000}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_PORT", "9090")
+
+	var got configTestArgs
+	cmd, err := NewCMD("config-test", WithConfigFile(path), WithConfig(func(config configTestArgs) error {
+		got = config
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Run(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got.Port != 9090 {
+		t.Errorf("expected env var to override config file, got %d", got.Port)
+	}
+}
+
+func TestCLIOverridesEnvAndConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"port":9000}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_PORT", "9090")
+
+	var got configTestArgs
+	cmd, err := NewCMD("config-test", WithConfigFile(path), WithConfig(func(config configTestArgs) error {
+		got = config
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Run([]string{"-port", "9999"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Port != 9999 {
+		t.Errorf("expected CLI flag to win, got %d", got.Port)
+	}
+}
+
+func TestStrictConfigRejectsUnknownKeys(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"host":"ok","nope":true}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd, err := NewCMD("config-test", WithConfigFile(path), WithStrictConfig(), WithConfig(func(config configTestArgs) error {
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Run(nil); err == nil {
+		t.Error("expected error for unknown config key in strict mode")
+	}
+}
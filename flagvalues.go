@@ -0,0 +1,298 @@
+package commandments
+
+import (
+	"encoding"
+	stdflag "flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithFlagParser registers a custom decoder for the named flag, used
+// instead of the builtin type dispatch. It lets callers support their own
+// types without implementing flag.Value or encoding.TextUnmarshaler.
+func WithFlagParser[T any](name string, parse func(string) (T, error)) Option {
+	return func(b *builder) error {
+		if b.customParsers == nil {
+			b.customParsers = map[string]func(string) (any, error){}
+		}
+		b.customParsers[name] = func(s string) (any, error) {
+			return parse(s)
+		}
+		return nil
+	}
+}
+
+var (
+	flagValueType       = reflect.TypeOf((*stdflag.Value)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+)
+
+// bindFlagValue registers a flag for field fv (name/usage already derived
+// from its tag) on fs, choosing the narrowest applicable strategy: a
+// custom parser, flag.Value/TextUnmarshaler implementation, a named type
+// (time.Duration, time.Time), a pointer, a slice/map, or a basic kind. It
+// returns an error instead of silently skipping a field it can't bind.
+func bindFlagValue(fs *stdflag.FlagSet, name, usage string, fv reflect.Value, customParsers map[string]func(string) (any, error)) error {
+	if parse, ok := customParsers[name]; ok {
+		fs.Var(&customValue{fv: fv, parse: parse}, name, usage)
+		return nil
+	}
+
+	if fv.CanAddr() {
+		addr := fv.Addr()
+		if addr.Type().Implements(flagValueType) {
+			fs.Var(addr.Interface().(stdflag.Value), name, usage)
+			return nil
+		}
+		if addr.Type().Implements(textUnmarshalerType) {
+			fs.Var(&textValue{dest: addr.Interface().(encoding.TextUnmarshaler)}, name, usage)
+			return nil
+		}
+	}
+
+	switch {
+	case fv.Type() == durationType:
+		fs.DurationVar(fv.Addr().Interface().(*time.Duration), name, time.Duration(fv.Int()), usage)
+		return nil
+	case fv.Type() == timeType:
+		fs.Var(&timeValue{dest: fv.Addr().Interface().(*time.Time)}, name, usage)
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fs.StringVar(fv.Addr().Interface().(*string), name, fv.String(), usage)
+	case reflect.Int:
+		fs.IntVar(fv.Addr().Interface().(*int), name, int(fv.Int()), usage)
+	case reflect.Bool:
+		fs.BoolVar(fv.Addr().Interface().(*bool), name, fv.Bool(), usage)
+	case reflect.Ptr:
+		fs.Var(&ptrValue{fv: fv}, name, usage)
+	case reflect.Slice:
+		switch fv.Type().Elem().Kind() {
+		case reflect.String:
+			fs.Var(&stringSliceValue{dest: fv.Addr().Interface().(*[]string)}, name, usage)
+		case reflect.Int:
+			fs.Var(&intSliceValue{dest: fv.Addr().Interface().(*[]int)}, name, usage)
+		default:
+			return fmt.Errorf("field has unsupported slice element kind %s", fv.Type().Elem().Kind())
+		}
+	case reflect.Map:
+		if fv.Type().Key().Kind() != reflect.String || fv.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map flag type %s (only map[string]string is supported)", fv.Type())
+		}
+		fs.Var(&stringMapValue{dest: fv.Addr().Interface().(*map[string]string)}, name, usage)
+	default:
+		return fmt.Errorf("unsupported flag kind %s", fv.Kind())
+	}
+	return nil
+}
+
+// customValue adapts a WithFlagParser decoder into a flag.Value.
+type customValue struct {
+	fv    reflect.Value
+	parse func(string) (any, error)
+	raw   string
+}
+
+func (c *customValue) Set(s string) error {
+	v, err := c.parse(s)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(v)
+	if !rv.Type().AssignableTo(c.fv.Type()) {
+		return fmt.Errorf("flag parser returned %s, want %s", rv.Type(), c.fv.Type())
+	}
+	c.fv.Set(rv)
+	c.raw = s
+	return nil
+}
+
+func (c *customValue) String() string { return c.raw }
+
+// textValue adapts an encoding.TextUnmarshaler (and, if also implemented,
+// TextMarshaler) into a flag.Value.
+type textValue struct {
+	dest encoding.TextUnmarshaler
+}
+
+func (t *textValue) Set(s string) error { return t.dest.UnmarshalText([]byte(s)) }
+
+func (t *textValue) String() string {
+	if m, ok := t.dest.(encoding.TextMarshaler); ok {
+		if b, err := m.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	return ""
+}
+
+// timeValue is a flag.Value for time.Time, parsed as RFC3339.
+type timeValue struct {
+	dest *time.Time
+}
+
+func (t *timeValue) Set(s string) error {
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return err
+	}
+	*t.dest = parsed
+	return nil
+}
+
+func (t *timeValue) String() string {
+	if t.dest == nil || t.dest.IsZero() {
+		return ""
+	}
+	return t.dest.Format(time.RFC3339)
+}
+
+// ptrValue is a flag.Value for a pointer field, so a flag left unset stays
+// nil instead of pointing at a zero value.
+type ptrValue struct {
+	fv reflect.Value
+}
+
+func (p *ptrValue) Set(s string) error {
+	elem := reflect.New(p.fv.Type().Elem())
+	switch p.fv.Type().Elem().Kind() {
+	case reflect.String:
+		elem.Elem().SetString(s)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		elem.Elem().SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		elem.Elem().SetBool(b)
+	default:
+		return fmt.Errorf("unsupported pointer element kind %s", p.fv.Type().Elem().Kind())
+	}
+	p.fv.Set(elem)
+	return nil
+}
+
+func (p *ptrValue) String() string {
+	if !p.fv.IsValid() || p.fv.IsNil() {
+		return ""
+	}
+	return fmt.Sprint(p.fv.Elem().Interface())
+}
+
+// stringSliceValue is a flag.Value for []string, accepting either
+// comma-separated values in one occurrence or repeated flag occurrences.
+// The first Set call replaces any default value.
+type stringSliceValue struct {
+	dest    *[]string
+	cleared bool
+}
+
+func (s *stringSliceValue) Set(v string) error {
+	if !s.cleared {
+		*s.dest = nil
+		s.cleared = true
+	}
+	*s.dest = append(*s.dest, strings.Split(v, ",")...)
+	return nil
+}
+
+func (s *stringSliceValue) String() string {
+	if s.dest == nil {
+		return ""
+	}
+	return strings.Join(*s.dest, ",")
+}
+
+// reset arranges for the next Set call to start a fresh layer (config
+// file, env, or CLI) instead of appending to whatever a prior layer left.
+func (s *stringSliceValue) reset() { s.cleared = false }
+
+// intSliceValue is a flag.Value for []int, with the same comma-separated
+// or repeated-occurrence semantics as stringSliceValue.
+type intSliceValue struct {
+	dest    *[]int
+	cleared bool
+}
+
+func (s *intSliceValue) Set(v string) error {
+	if !s.cleared {
+		*s.dest = nil
+		s.cleared = true
+	}
+	for _, part := range strings.Split(v, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return fmt.Errorf("invalid int %q: %w", part, err)
+		}
+		*s.dest = append(*s.dest, n)
+	}
+	return nil
+}
+
+func (s *intSliceValue) String() string {
+	if s.dest == nil {
+		return ""
+	}
+	parts := make([]string, len(*s.dest))
+	for i, n := range *s.dest {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ",")
+}
+
+// reset arranges for the next Set call to start a fresh layer (config
+// file, env, or CLI) instead of appending to whatever a prior layer left.
+func (s *intSliceValue) reset() { s.cleared = false }
+
+// stringMapValue is a flag.Value for map[string]string, parsed as
+// comma-separated key=value pairs. The first Set call replaces any
+// default value.
+type stringMapValue struct {
+	dest    *map[string]string
+	cleared bool
+}
+
+func (m *stringMapValue) Set(v string) error {
+	if !m.cleared {
+		*m.dest = map[string]string{}
+		m.cleared = true
+	}
+	for _, pair := range strings.Split(v, ",") {
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid map entry %q (want key=value)", pair)
+		}
+		(*m.dest)[kv[0]] = kv[1]
+	}
+	return nil
+}
+
+func (m *stringMapValue) String() string {
+	if m.dest == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(*m.dest))
+	for k, v := range *m.dest {
+		parts = append(parts, k+"="+v)
+	}
+	return strings.Join(parts, ",")
+}
+
+// reset arranges for the next Set call to start a fresh layer (config
+// file, env, or CLI) instead of appending to whatever a prior layer left.
+func (m *stringMapValue) reset() { m.cleared = false }
@@ -0,0 +1,170 @@
+package commandments
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+type richTestArgs struct {
+	Timeout time.Duration     `flag:"timeout,request timeout"`
+	Tags    []string          `flag:"tags,comma-separated tags"`
+	Ports   []int             `flag:"ports,comma-separated ports"`
+	Labels  map[string]string `flag:"labels,k=v labels"`
+	Region  *string           `flag:"region,optional region override"`
+}
+
+func TestBindFlagsRichKinds(t *testing.T) {
+	var got richTestArgs
+	cmd, err := NewCMD("rich", WithConfig(func(config richTestArgs) error {
+		got = config
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmd.Run([]string{
+		"-timeout", "2s",
+		"-tags", "a,b,c",
+		"-ports", "80",
+		"-ports", "443",
+		"-labels", "env=prod,team=infra",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Timeout != 2*time.Second {
+		t.Errorf("expected 2s timeout, got %s", got.Timeout)
+	}
+	if len(got.Tags) != 3 || got.Tags[0] != "a" {
+		t.Errorf("expected [a b c] tags, got %v", got.Tags)
+	}
+	if len(got.Ports) != 2 || got.Ports[0] != 80 || got.Ports[1] != 443 {
+		t.Errorf("expected repeated --ports to accumulate, got %v", got.Ports)
+	}
+	if got.Labels["env"] != "prod" || got.Labels["team"] != "infra" {
+		t.Errorf("expected parsed labels map, got %v", got.Labels)
+	}
+	if got.Region != nil {
+		t.Errorf("expected unset pointer flag to stay nil, got %v", *got.Region)
+	}
+}
+
+func TestBindFlagsUnsetPointerIsSetWhenProvided(t *testing.T) {
+	var got richTestArgs
+	cmd, err := NewCMD("rich", WithConfig(func(config richTestArgs) error {
+		got = config
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Run([]string{"-region", "us-east-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Region == nil || *got.Region != "us-east-1" {
+		t.Errorf("expected region pointer to be set, got %v", got.Region)
+	}
+}
+
+type customParserArgs struct {
+	Level int `flag:"level,severity level"`
+}
+
+func TestEnvVarAppliesToRichKinds(t *testing.T) {
+	t.Setenv("RICH_TIMEOUT", "5s")
+	t.Setenv("RICH_TAGS", "a,b,c")
+
+	var got richTestArgs
+	cmd, err := NewCMD("rich", WithEnvPrefix("RICH"), WithConfig(func(config richTestArgs) error {
+		got = config
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Run(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got.Timeout != 5*time.Second {
+		t.Errorf("expected env var to set a time.Duration field, got %s", got.Timeout)
+	}
+	if len(got.Tags) != 3 || got.Tags[0] != "a" {
+		t.Errorf("expected env var to set a []string field, got %v", got.Tags)
+	}
+}
+
+func TestConfigFileAppliesToDuration(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/config.json"
+	if err := os.WriteFile(path, []byte(`{"timeout":"5s","tags":["x","y"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var got richTestArgs
+	cmd, err := NewCMD("rich", WithConfigFile(path), WithConfig(func(config richTestArgs) error {
+		got = config
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Run(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got.Timeout != 5*time.Second {
+		t.Errorf("expected config file to set a time.Duration field, got %s", got.Timeout)
+	}
+	if len(got.Tags) != 2 || got.Tags[0] != "x" || got.Tags[1] != "y" {
+		t.Errorf("expected config file array to set a []string field, got %v", got.Tags)
+	}
+}
+
+func TestEnvReplacesConfigFileSliceInsteadOfAppending(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/config.json"
+	if err := os.WriteFile(path, []byte(`{"tags":["from-file"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("RICH_TAGS", "from-env")
+
+	var got richTestArgs
+	cmd, err := NewCMD("rich", WithConfigFile(path), WithEnvPrefix("RICH"), WithConfig(func(config richTestArgs) error {
+		got = config
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Run(nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tags) != 1 || got.Tags[0] != "from-env" {
+		t.Errorf("expected env var to replace the config file's slice value, got %v", got.Tags)
+	}
+}
+
+func TestWithFlagParser(t *testing.T) {
+	levels := map[string]int{"low": 1, "medium": 2, "high": 3}
+
+	var got customParserArgs
+	cmd, err := NewCMD("custom",
+		WithFlagParser("level", func(s string) (int, error) {
+			return levels[s], nil
+		}),
+		WithConfig(func(config customParserArgs) error {
+			got = config
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Run([]string{"-level", "high"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Level != 3 {
+		t.Errorf("expected custom parser to map \"high\" to 3, got %d", got.Level)
+	}
+}
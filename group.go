@@ -0,0 +1,291 @@
+package commandments
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Group composes commands and nested groups into a subcommand tree, e.g.
+// "myapp server start --port=8080" or "myapp db migrate up".
+type Group struct {
+	name      string
+	shortDesc string
+
+	commands map[string]*CMD
+	groups   map[string]*Group
+	order    []string
+
+	persistent []persistentFlag
+}
+
+// GroupOption configures a Group during construction via NewGroup.
+type GroupOption func(*Group)
+
+// NewGroup creates a named node in a subcommand tree.
+func NewGroup(name string, opts ...GroupOption) *Group {
+	g := &Group{
+		name:     name,
+		commands: map[string]*CMD{},
+		groups:   map[string]*Group{},
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// WithGroupDescription sets the one-line help text shown for a Group.
+func WithGroupDescription(desc string) GroupOption {
+	return func(g *Group) { g.shortDesc = desc }
+}
+
+// persistentFlag is a flag shared by a Group and every command reachable
+// beneath it.
+type persistentFlag struct {
+	name  string
+	usage string
+	kind  reflect.Kind
+	ptr   any
+}
+
+// PersistentString declares a string flag available on g and all of its
+// descendants, returning the value it will be parsed into.
+func (g *Group) PersistentString(name, def, usage string) *string {
+	v := new(string)
+	*v = def
+	g.persistent = append(g.persistent, persistentFlag{name: name, usage: usage, kind: reflect.String, ptr: v})
+	return v
+}
+
+// PersistentInt declares an int flag available on g and all of its
+// descendants, returning the value it will be parsed into.
+func (g *Group) PersistentInt(name string, def int, usage string) *int {
+	v := new(int)
+	*v = def
+	g.persistent = append(g.persistent, persistentFlag{name: name, usage: usage, kind: reflect.Int, ptr: v})
+	return v
+}
+
+// PersistentBool declares a bool flag available on g and all of its
+// descendants, returning the value it will be parsed into.
+func (g *Group) PersistentBool(name string, def bool, usage string) *bool {
+	v := new(bool)
+	*v = def
+	g.persistent = append(g.persistent, persistentFlag{name: name, usage: usage, kind: reflect.Bool, ptr: v})
+	return v
+}
+
+// AddCommand registers cmd as a named subcommand of g.
+func (g *Group) AddCommand(name string, cmd *CMD) *Group {
+	g.commands[name] = cmd
+	g.order = append(g.order, name)
+	return g
+}
+
+// AddGroup registers child as a nested subcommand group of g.
+func (g *Group) AddGroup(child *Group) *Group {
+	g.groups[child.name] = child
+	g.order = append(g.order, child.name)
+	return g
+}
+
+// Run dispatches os.Args (including the program name at index 0) to the
+// matching leaf command. A persistent flag may appear anywhere after the
+// name of the group that declares it, before, between, or after the
+// remaining subcommand names (e.g. for a flag declared on g, both
+// "myapp --verbose server start" and "myapp server start --verbose" work);
+// a flag declared on a nested group is not recognized before that group's
+// name. A hidden
+// "--generate-<shell>-completion" flag anywhere in args short-circuits Run
+// to print a completion script covering the whole tree instead.
+func (g *Group) Run(args []string) error {
+	if len(args) > 0 {
+		args = args[1:]
+	}
+	if shell, ok := generateCompletionShell(args); ok {
+		script, err := g.Completion(shell)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(os.Stdout, script)
+		return nil
+	}
+	return g.dispatch(args, nil)
+}
+
+func (g *Group) dispatch(args []string, inherited []persistentFlag) error {
+	all := append(append([]persistentFlag{}, inherited...), g.persistent...)
+
+	remaining, err := extractPersistentFlags(args, all)
+	if err != nil {
+		return fmt.Errorf("commandments: %s: %w", g.name, err)
+	}
+	if len(remaining) == 0 {
+		return fmt.Errorf("commandments: %s: missing subcommand", g.name)
+	}
+	head, rest := remaining[0], remaining[1:]
+
+	if isHelpFlag([]string{head}) {
+		g.writeHelp(os.Stdout)
+		return nil
+	}
+	if child, ok := g.groups[head]; ok {
+		return child.dispatch(rest, all)
+	}
+	if cmd, ok := g.commands[head]; ok {
+		return cmd.Run(rest)
+	}
+	return fmt.Errorf("commandments: %s: unknown subcommand %q", g.name, head)
+}
+
+// extractPersistentFlags scans args for occurrences of the named flags in
+// flags, setting each match's destination and removing its token(s) from
+// the result, so a persistent flag can appear anywhere relative to the
+// subcommand names rather than only after the whole path.
+func extractPersistentFlags(args []string, flags []persistentFlag) ([]string, error) {
+	byName := make(map[string]persistentFlag, len(flags))
+	for _, pf := range flags {
+		byName[pf.name] = pf
+	}
+
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if !strings.HasPrefix(a, "-") {
+			remaining = append(remaining, a)
+			continue
+		}
+		name, value, hasValue := splitFlagToken(a)
+		pf, ok := byName[name]
+		if !ok {
+			remaining = append(remaining, a)
+			continue
+		}
+		if !hasValue {
+			if pf.kind == reflect.Bool {
+				value = "true"
+			} else if i+1 < len(args) {
+				i++
+				value = args[i]
+			} else {
+				return nil, fmt.Errorf("flag needs an argument: -%s", name)
+			}
+		}
+		if err := setPersistentValue(pf, value); err != nil {
+			return nil, err
+		}
+	}
+	return remaining, nil
+}
+
+// splitFlagToken parses a "-name", "-name=value", "--name", or
+// "--name=value" token. The caller must already know tok starts with "-".
+func splitFlagToken(tok string) (name, value string, hasValue bool) {
+	tok = strings.TrimPrefix(strings.TrimPrefix(tok, "-"), "-")
+	if eq := strings.IndexByte(tok, '='); eq >= 0 {
+		return tok[:eq], tok[eq+1:], true
+	}
+	return tok, "", false
+}
+
+// setPersistentValue parses value according to pf's kind and writes it
+// into pf's destination.
+func setPersistentValue(pf persistentFlag, value string) error {
+	switch pf.kind {
+	case reflect.String:
+		*pf.ptr.(*string) = value
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for flag -%s: %w", value, pf.name, err)
+		}
+		*pf.ptr.(*int) = n
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for flag -%s: %w", value, pf.name, err)
+		}
+		*pf.ptr.(*bool) = b
+	}
+	return nil
+}
+
+// writeHelp renders g's help screen: its name, description, and the list
+// of subcommands/groups reachable directly beneath it.
+func (g *Group) writeHelp(w io.Writer) {
+	fmt.Fprint(w, g.name)
+	if g.shortDesc != "" {
+		fmt.Fprintf(w, " - %s", g.shortDesc)
+	}
+	fmt.Fprintln(w)
+
+	if len(g.order) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Subcommands:")
+	for _, name := range g.order {
+		var desc string
+		if child, ok := g.groups[name]; ok {
+			desc = child.shortDesc
+		} else if cmd, ok := g.commands[name]; ok {
+			desc = cmd.shortDesc
+		}
+		if desc != "" {
+			fmt.Fprintf(w, "  %s - %s\n", name, desc)
+		} else {
+			fmt.Fprintf(w, "  %s\n", name)
+		}
+	}
+}
+
+// collectTree walks g and every descendant group and command, gathering
+// every persistent flag and every subcommand/group name reachable at any
+// depth. Names and flags are deduplicated, first occurrence wins.
+func (g *Group) collectTree() ([]flag, []string) {
+	seenFlags := map[string]bool{}
+	var flags []flag
+	seenNames := map[string]bool{}
+	var names []string
+
+	addFlag := func(f flag) {
+		if seenFlags[f.name] {
+			return
+		}
+		seenFlags[f.name] = true
+		flags = append(flags, f)
+	}
+	addName := func(name string) {
+		if seenNames[name] {
+			return
+		}
+		seenNames[name] = true
+		names = append(names, name)
+	}
+
+	var walk func(node *Group)
+	walk = func(node *Group) {
+		for _, pf := range node.persistent {
+			addFlag(flag{name: pf.name, usage: pf.usage, kind: pf.kind})
+		}
+		for _, name := range node.order {
+			addName(name)
+			if child, ok := node.groups[name]; ok {
+				walk(child)
+				continue
+			}
+			if cmd, ok := node.commands[name]; ok {
+				for _, f := range cmd.flags() {
+					addFlag(f)
+				}
+			}
+		}
+	}
+	walk(g)
+
+	return flags, names
+}
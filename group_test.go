@@ -0,0 +1,128 @@
+package commandments
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type groupTestArgs struct {
+	Port int `flag:"port,set port"`
+}
+
+func TestGroupDispatch(t *testing.T) {
+	var gotPort int
+	var gotVerbose bool
+
+	start, err := NewCMD("start", WithConfig(func(config groupTestArgs) error {
+		gotPort = config.Port
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := NewGroup("myapp", WithGroupDescription("example app"))
+	verbose := root.PersistentBool("verbose", false, "enable verbose logging")
+
+	server := NewGroup("server")
+	server.AddCommand("start", start)
+	root.AddGroup(server)
+
+	if err := root.Run([]string{"myapp", "server", "start", "--port", "8080", "--verbose"}); err != nil {
+		t.Fatal(err)
+	}
+	gotVerbose = *verbose
+
+	if gotPort != 8080 {
+		t.Errorf("expected port 8080, got %d", gotPort)
+	}
+	if !gotVerbose {
+		t.Error("expected persistent --verbose flag to propagate to the leaf command")
+	}
+}
+
+func TestGroupUnknownSubcommand(t *testing.T) {
+	root := NewGroup("myapp")
+	if err := root.Run([]string{"myapp", "nope"}); err == nil {
+		t.Error("expected an error for an unknown subcommand")
+	}
+}
+
+func TestGroupPersistentFlagBeforeSubcommandPath(t *testing.T) {
+	var gotPort int
+
+	start, err := NewCMD("start", WithConfig(func(config groupTestArgs) error {
+		gotPort = config.Port
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := NewGroup("myapp")
+	verbose := root.PersistentBool("verbose", false, "enable verbose logging")
+
+	server := NewGroup("server")
+	server.AddCommand("start", start)
+	root.AddGroup(server)
+
+	if err := root.Run([]string{"myapp", "--verbose", "server", "start", "--port", "8080"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotPort != 8080 {
+		t.Errorf("expected port 8080, got %d", gotPort)
+	}
+	if !*verbose {
+		t.Error("expected persistent --verbose flag to be recognized before the subcommand path")
+	}
+}
+
+func TestGroupPersistentFlagNamedConfigIsNotDropped(t *testing.T) {
+	var gotPort int
+
+	start, err := NewCMD("start", WithConfig(func(config groupTestArgs) error {
+		gotPort = config.Port
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := NewGroup("myapp")
+	cfg := root.PersistentString("config", "", "path to a shared config file")
+	root.AddCommand("start", start)
+
+	if err := root.Run([]string{"myapp", "start", "--config", "/tmp/shared.json", "--port", "8080"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotPort != 8080 {
+		t.Errorf("expected port 8080, got %d", gotPort)
+	}
+	if *cfg != "/tmp/shared.json" {
+		t.Errorf("expected persistent --config flag to be set, got %q", *cfg)
+	}
+}
+
+func TestGroupHelpListsDescriptionAndSubcommands(t *testing.T) {
+	start, err := NewCMD("start", WithConfig(func(config groupTestArgs) error { return nil }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewGroup("server", WithGroupDescription("manage the server"))
+	server.AddCommand("start", start)
+
+	root := NewGroup("myapp", WithGroupDescription("example app"))
+	root.AddGroup(server)
+
+	var buf bytes.Buffer
+	root.writeHelp(&buf)
+	out := buf.String()
+
+	for _, want := range []string{"myapp", "example app", "server", "manage the server"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected help output to mention %q, got:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package commandments
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// WithShortDescription sets the one-line description shown in -h/--help
+// output and in generated docs.
+func WithShortDescription(desc string) Option {
+	return func(b *builder) error {
+		b.shortDesc = desc
+		return nil
+	}
+}
+
+// WithLongDescription sets the extended description shown in -h/--help
+// output and in generated docs, below the short description.
+func WithLongDescription(desc string) Option {
+	return func(b *builder) error {
+		b.longDesc = desc
+		return nil
+	}
+}
+
+// isHelpFlag reports whether args requests help, so Run can short-circuit
+// before flag parsing, config loading, or validation.
+func isHelpFlag(args []string) bool {
+	for _, a := range args {
+		if a == "-h" || a == "--help" {
+			return true
+		}
+	}
+	return false
+}
+
+// helpFlag is a flag's rendered help metadata: its reflected type, tagged
+// choices, declared default, and whether it's required.
+type helpFlag struct {
+	name     string
+	usage    string
+	kind     string
+	def      string
+	required bool
+	choices  []string
+}
+
+// helpFlags derives help metadata for every tagged field of c's
+// configuration struct, in declaration order.
+func (c *CMD) helpFlags() []helpFlag {
+	t := c.cfgVal.Type()
+	out := make([]helpFlag, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("flag")
+		if tag == "" {
+			continue
+		}
+		name, usage := splitFlagTag(tag)
+		fv := c.cfgVal.Field(i)
+		out = append(out, helpFlag{
+			name:     name,
+			usage:    usage,
+			kind:     fv.Type().String(),
+			def:      defaultString(fv),
+			required: field.Tag.Get("required") == "true",
+			choices:  parseChoices(field.Tag.Get("choices")),
+		})
+	}
+	return out
+}
+
+func defaultString(fv reflect.Value) string {
+	if fv.IsZero() {
+		return ""
+	}
+	return fmt.Sprint(fv.Interface())
+}
+
+// writeHelp renders the command's -h/--help screen: name, descriptions,
+// and a grouped flag list with types, defaults, and required markers.
+func (c *CMD) writeHelp(w io.Writer) {
+	fmt.Fprint(w, c.name)
+	if c.shortDesc != "" {
+		fmt.Fprintf(w, " - %s", c.shortDesc)
+	}
+	fmt.Fprintln(w)
+
+	if c.longDesc != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, c.longDesc)
+	}
+
+	flags := c.helpFlags()
+	if len(flags) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Flags:")
+	for _, f := range flags {
+		fmt.Fprintf(w, "  --%s %s\n", f.name, f.kind)
+		if f.usage != "" {
+			fmt.Fprintf(w, "        %s\n", f.usage)
+		}
+		if f.required {
+			fmt.Fprintln(w, "        (required)")
+		}
+		if f.def != "" {
+			fmt.Fprintf(w, "        (default %s)\n", f.def)
+		}
+		if len(f.choices) > 0 {
+			fmt.Fprintf(w, "        (choices: %s)\n", strings.Join(f.choices, ", "))
+		}
+	}
+}
+
+// Manpage writes a roff man(1) page for c to w, driven from the same
+// reflected flag metadata as -h/--help, so the page can't drift from the
+// code.
+func (c *CMD) Manpage(w io.Writer) error {
+	fmt.Fprintf(w, ".TH %s 1\n", strings.ToUpper(c.name))
+	fmt.Fprintf(w, ".SH NAME\n%s", c.name)
+	if c.shortDesc != "" {
+		fmt.Fprintf(w, " \\- %s", c.shortDesc)
+	}
+	fmt.Fprintln(w)
+
+	if c.longDesc != "" {
+		fmt.Fprintf(w, ".SH DESCRIPTION\n%s\n", c.longDesc)
+	}
+
+	flags := c.helpFlags()
+	if len(flags) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w, ".SH FLAGS")
+	for _, f := range flags {
+		fmt.Fprintf(w, ".TP\n\\fB--%s\\fR %s\n", f.name, f.kind)
+		if f.usage != "" {
+			fmt.Fprintln(w, f.usage)
+		}
+		if f.required {
+			fmt.Fprintln(w, "(required)")
+		}
+		if f.def != "" {
+			fmt.Fprintf(w, "(default %s)\n", f.def)
+		}
+	}
+	return nil
+}
+
+// Markdown writes a Markdown reference page for c to w, suitable for docs
+// sites, driven from the same reflected flag metadata as -h/--help.
+func (c *CMD) Markdown(w io.Writer) error {
+	fmt.Fprintf(w, "# %s\n\n", c.name)
+	if c.shortDesc != "" {
+		fmt.Fprintf(w, "%s\n\n", c.shortDesc)
+	}
+	if c.longDesc != "" {
+		fmt.Fprintf(w, "%s\n\n", c.longDesc)
+	}
+
+	flags := c.helpFlags()
+	if len(flags) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w, "## Flags")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Flag | Type | Description | Default | Required |")
+	fmt.Fprintln(w, "| --- | --- | --- | --- | --- |")
+	for _, f := range flags {
+		required := ""
+		if f.required {
+			required = "yes"
+		}
+		fmt.Fprintf(w, "| `--%s` | `%s` | %s | %s | %s |\n", f.name, f.kind, f.usage, f.def, required)
+	}
+	return nil
+}
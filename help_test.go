@@ -0,0 +1,57 @@
+package commandments
+
+import (
+	"strings"
+	"testing"
+)
+
+type helpTestArgs struct {
+	Port int    `flag:"port,set port number" required:"true"`
+	Host string `flag:"host,set host"`
+}
+
+func TestRunHelpFlagShortCircuits(t *testing.T) {
+	called := false
+	cmd, err := NewCMD("helpcmd",
+		WithShortDescription("a test command"),
+		WithConfig(func(config helpTestArgs) error {
+			called = true
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Run([]string{"--help"}); err != nil {
+		t.Fatal(err)
+	}
+	if called {
+		t.Error("expected --help to short-circuit before the WithConfig callback runs")
+	}
+}
+
+func TestManpageAndMarkdownIncludeFlagMetadata(t *testing.T) {
+	cmd, err := NewCMD("helpcmd",
+		WithShortDescription("a test command"),
+		WithConfig(func(config helpTestArgs) error { return nil }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var man strings.Builder
+	if err := cmd.Manpage(&man); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(man.String(), "--port") || !strings.Contains(man.String(), "(required)") {
+		t.Errorf("expected man page to mention --port as required, got:\n%s", man.String())
+	}
+
+	var md strings.Builder
+	if err := cmd.Markdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md.String(), "`--port`") || !strings.Contains(md.String(), "`--host`") {
+		t.Errorf("expected markdown table to list both flags, got:\n%s", md.String())
+	}
+}
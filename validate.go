@@ -0,0 +1,131 @@
+package commandments
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WithValidator registers an arbitrary validation callback, run against
+// the parsed configuration after struct-tag validation (`required`,
+// `validate`) and before the WithConfig callback.
+func WithValidator[T any](fn func(cfg T) error) Option {
+	return func(b *builder) error {
+		b.validators = append(b.validators, func(cfg any) error {
+			return fn(cfg.(T))
+		})
+		return nil
+	}
+}
+
+// ValidationErrors aggregates every flag validation failure from a single
+// Run, so callers see every problem at once instead of just the first.
+type ValidationErrors []error
+
+func (v ValidationErrors) Error() string {
+	msgs := make([]string, len(v))
+	for i, err := range v {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// validateStructTags enforces `required:"true"` and `validate:"..."`
+// struct tags against cfg, aggregating every failure into one
+// ValidationErrors.
+func validateStructTags(cfgType reflect.Type, cfg reflect.Value) error {
+	var errs ValidationErrors
+	for i := 0; i < cfgType.NumField(); i++ {
+		field := cfgType.Field(i)
+		tag := field.Tag.Get("flag")
+		if tag == "" {
+			continue
+		}
+		name, _ := splitFlagTag(tag)
+		fv := cfg.Field(i)
+
+		if field.Tag.Get("required") == "true" && fv.IsZero() {
+			errs = append(errs, fmt.Errorf("--%s is required", name))
+		}
+		if rule := field.Tag.Get("validate"); rule != "" {
+			errs = append(errs, validateRules(name, fv, rule)...)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// validateRules applies a comma-separated `validate:"..."` tag (e.g.
+// "min=1,max=65535" or "url") to fv.
+func validateRules(name string, fv reflect.Value, rule string) []error {
+	var errs []error
+	for _, r := range strings.Split(rule, ",") {
+		r = strings.TrimSpace(r)
+		switch {
+		case r == "":
+			continue
+		case r == "url":
+			if err := validateURLRule(name, fv); err != nil {
+				errs = append(errs, err)
+			}
+		case strings.HasPrefix(r, "min="):
+			if err := validateBoundRule(name, fv, strings.TrimPrefix(r, "min="), false); err != nil {
+				errs = append(errs, err)
+			}
+		case strings.HasPrefix(r, "max="):
+			if err := validateBoundRule(name, fv, strings.TrimPrefix(r, "max="), true); err != nil {
+				errs = append(errs, err)
+			}
+		default:
+			errs = append(errs, fmt.Errorf("--%s: unknown validation rule %q", name, r))
+		}
+	}
+	return errs
+}
+
+func validateURLRule(name string, fv reflect.Value) error {
+	if fv.Kind() != reflect.String {
+		return fmt.Errorf("--%s: validate=url only applies to string fields", name)
+	}
+	if fv.String() == "" {
+		return nil
+	}
+	u, err := url.ParseRequestURI(fv.String())
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("--%s must be a valid URL", name)
+	}
+	return nil
+}
+
+func validateBoundRule(name string, fv reflect.Value, bound string, isMax bool) error {
+	n, err := strconv.ParseFloat(bound, 64)
+	if err != nil {
+		return fmt.Errorf("--%s: invalid bound %q", name, bound)
+	}
+	v, ok := numericValue(fv)
+	if !ok {
+		return fmt.Errorf("--%s: min/max validation only applies to numeric fields", name)
+	}
+	if isMax && v > n {
+		return fmt.Errorf("--%s must be <= %s", name, bound)
+	}
+	if !isMax && v < n {
+		return fmt.Errorf("--%s must be >= %s", name, bound)
+	}
+	return nil
+}
+
+func numericValue(fv reflect.Value) (float64, bool) {
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(fv.Int()), true
+	case reflect.Float32, reflect.Float64:
+		return fv.Float(), true
+	default:
+		return 0, false
+	}
+}
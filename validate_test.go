@@ -0,0 +1,64 @@
+package commandments
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type validatedArgs struct {
+	Port int    `flag:"port,set port" required:"true" validate:"min=1,max=65535"`
+	URL  string `flag:"url,set url" validate:"url"`
+}
+
+func TestValidateStructTagsAggregatesFailures(t *testing.T) {
+	cmd, err := NewCMD("validated", WithConfig(func(config validatedArgs) error { return nil }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmd.Run([]string{"-port", "0", "-url", "not-a-url"})
+	if err == nil {
+		t.Fatal("expected a validation error")
+	}
+	t.Log(err)
+	for _, want := range []string{"--port must be >= 1", "--url must be a valid URL"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected aggregated error to mention %q, got: %v", want, err)
+		}
+	}
+}
+
+func TestValidateStructTagsPasses(t *testing.T) {
+	var got validatedArgs
+	cmd, err := NewCMD("validated", WithConfig(func(config validatedArgs) error {
+		got = config
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Run([]string{"-port", "8080", "-url", "https://example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Port != 8080 {
+		t.Errorf("expected port 8080, got %d", got.Port)
+	}
+}
+
+func TestWithValidatorRunsAfterStructTags(t *testing.T) {
+	cmd, err := NewCMD("validated",
+		WithConfig(func(config validatedArgs) error { return nil }),
+		WithValidator(func(config validatedArgs) error {
+			if config.Port == 8081 {
+				return errors.New("port 8081 is reserved")
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Run([]string{"-port", "8081", "-url", "https://example.com"}); err == nil {
+		t.Error("expected WithValidator to reject port 8081")
+	}
+}